@@ -0,0 +1,231 @@
+package rhh
+
+import "math/bits"
+
+// DefaultHopscotchNeighborhood is the neighborhood size used when
+// Options.Neighborhood is unset.
+const DefaultHopscotchNeighborhood = 32
+
+// MaxHopscotchNeighborhood is the largest neighborhood size supported, since
+// each bucket's membership is tracked in a single uint64 bitmap.
+const MaxHopscotchNeighborhood = 63
+
+// HopscotchMap is a hash map that implements Hopscotch Hashing. Unlike
+// HashMap, every key that hashes to a given bucket is guaranteed to live
+// within a fixed H-slot neighborhood of that bucket, so Get never probes
+// more than H slots even at high load factors, where HashMap's probe
+// distances start to grow noticeably. The tradeoff is a more involved Put,
+// which may need to "hop" slots closer to make room.
+// https://en.wikipedia.org/wiki/Hopscotch_hashing
+type HopscotchMap[K any, V any] struct {
+	hasher Hasher[K]
+
+	hashes  []uint64
+	elems   []hashElem[K, V]
+	hopInfo []uint64 // per-bucket bitmap of which neighborhood slots it owns
+
+	h          int
+	n          int
+	capacity   int
+	threshold  int
+	mask       uint64
+	loadFactor int
+}
+
+// NewHopscotchMap returns a new HopscotchMap that hashes and compares keys
+// using hasher.
+func NewHopscotchMap[K any, V any](hasher Hasher[K], opt Options) *HopscotchMap[K, V] {
+	h := opt.Neighborhood
+	switch {
+	case h <= 0:
+		h = DefaultHopscotchNeighborhood
+	case h > MaxHopscotchNeighborhood:
+		h = MaxHopscotchNeighborhood
+	}
+
+	m := &HopscotchMap[K, V]{
+		hasher:     hasher,
+		h:          h,
+		capacity:   pow2(opt.Capacity),
+		loadFactor: opt.LoadFactor,
+	}
+	m.alloc()
+	return m
+}
+
+// Get returns the value for key and whether it was found. Only the bits set
+// in home's hopInfo bitmap are ever examined.
+func (m *HopscotchMap[K, V]) Get(key K) (V, bool) {
+	hash := m.hasher.Hash(key)
+	home := int(hash & m.mask)
+
+	for bitmap := m.hopInfo[home]; bitmap != 0; {
+		i := bits.TrailingZeros64(bitmap)
+		bitmap &^= 1 << uint(i)
+
+		pos := int((uint64(home) + uint64(i)) & m.mask)
+		if m.hashes[pos] == hash && m.hasher.Equal(m.elems[pos].key, key) {
+			return m.elems[pos].value, true
+		}
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Put inserts key and val into the map, overwriting any existing value.
+func (m *HopscotchMap[K, V]) Put(key K, val V) {
+	hash := m.hasher.Hash(key)
+	home := int(hash & m.mask)
+
+	for bitmap := m.hopInfo[home]; bitmap != 0; {
+		i := bits.TrailingZeros64(bitmap)
+		bitmap &^= 1 << uint(i)
+
+		pos := int((uint64(home) + uint64(i)) & m.mask)
+		if m.hashes[pos] == hash && m.hasher.Equal(m.elems[pos].key, key) {
+			m.elems[pos].value = val
+			return
+		}
+	}
+
+	m.n++
+	if m.n > m.threshold {
+		m.grow()
+		home = int(hash & m.mask)
+	}
+	m.insert(hash, home, key, val)
+}
+
+// Delete removes key from the hash map and returns true if the key existed.
+func (m *HopscotchMap[K, V]) Delete(key K) bool {
+	hash := m.hasher.Hash(key)
+	home := int(hash & m.mask)
+
+	for bitmap := m.hopInfo[home]; bitmap != 0; {
+		i := bits.TrailingZeros64(bitmap)
+		bitmap &^= 1 << uint(i)
+
+		pos := int((uint64(home) + uint64(i)) & m.mask)
+		if m.hashes[pos] == hash && m.hasher.Equal(m.elems[pos].key, key) {
+			m.hashes[pos] = 0
+			m.elems[pos] = hashElem[K, V]{}
+			m.hopInfo[home] &^= 1 << uint(i)
+			m.n--
+			return true
+		}
+	}
+	return false
+}
+
+// insert places key/val in home's neighborhood, linearly probing for a free
+// slot and then hopping it backward until it falls within H of home.
+func (m *HopscotchMap[K, V]) insert(hash uint64, home int, key K, val V) {
+	free := home
+	for m.hashes[free] != 0 {
+		free = int((uint64(free) + 1) & m.mask)
+	}
+
+	for m.dist(home, free) >= m.h {
+		moved := false
+
+		// Search buckets within H-1 slots before free for an entry that can
+		// be moved into free without leaving its own neighborhood.
+		for d := m.h - 1; d > 0 && !moved; d-- {
+			b := int((uint64(free) + uint64(m.capacity) - uint64(d)) & m.mask)
+			bitmap := m.hopInfo[b]
+			for bi := 0; bi < d; bi++ {
+				if bitmap&(1<<uint(bi)) == 0 {
+					continue
+				}
+				src := int((uint64(b) + uint64(bi)) & m.mask)
+
+				m.hashes[free] = m.hashes[src]
+				m.elems[free] = m.elems[src]
+				m.hopInfo[b] = m.hopInfo[b]&^(1<<uint(bi)) | (1 << uint(d))
+
+				m.hashes[src] = 0
+				m.elems[src] = hashElem[K, V]{}
+
+				free = src
+				moved = true
+				break
+			}
+		}
+
+		if !moved {
+			// Nothing within reach of free can be hopped; H is too small
+			// for the current load. Growing gives every bucket a fresh,
+			// emptier neighborhood.
+			m.grow()
+			m.insert(hash, int(hash&m.mask), key, val)
+			return
+		}
+	}
+
+	m.hashes[free] = hash
+	m.elems[free] = hashElem[K, V]{hash: hash, key: key, value: val}
+	m.hopInfo[home] |= 1 << uint(m.dist(home, free))
+}
+
+// dist returns pos's offset from home, wrapping on capacity.
+func (m *HopscotchMap[K, V]) dist(home, pos int) int {
+	return int((uint64(pos) + uint64(m.capacity) - uint64(home)) & m.mask)
+}
+
+// alloc elems, hashes and hopInfo according to currently set capacity.
+func (m *HopscotchMap[K, V]) alloc() {
+	m.elems = make([]hashElem[K, V], m.capacity)
+	m.hashes = make([]uint64, m.capacity)
+	m.hopInfo = make([]uint64, m.capacity)
+	m.threshold = (m.capacity * m.loadFactor) / 100
+	m.mask = uint64(m.capacity - 1)
+}
+
+// grow doubles the capacity and reinserts all existing elements.
+func (m *HopscotchMap[K, V]) grow() {
+	elems, hashes := m.elems, m.hashes
+	capacity := m.capacity
+
+	m.capacity *= 2
+	m.alloc()
+
+	for i := 0; i < capacity; i++ {
+		hash := hashes[i]
+		if hash == 0 {
+			continue
+		}
+		m.insert(hash, int(hash&m.mask), elems[i].key, elems[i].value)
+	}
+}
+
+// Len returns the number of key/values set in map.
+func (m *HopscotchMap[K, V]) Len() int { return m.n }
+
+// Cap returns the number of slots in the map.
+func (m *HopscotchMap[K, V]) Cap() int { return m.capacity }
+
+// AverageProbeCount returns the average number of probes for each element.
+func (m *HopscotchMap[K, V]) AverageProbeCount() float64 {
+	var sum float64
+	for i := 0; i < m.capacity; i++ {
+		hash := m.hashes[i]
+		if hash == 0 {
+			continue
+		}
+		sum += float64(m.dist(int(hash&m.mask), i))
+	}
+	return sum/float64(m.n) + 1.0
+}
+
+// Keys returns an unsorted list of keys in the map.
+func (m *HopscotchMap[K, V]) Keys() []K {
+	a := make([]K, 0, m.n)
+	for i := 0; i < m.capacity; i++ {
+		if m.hashes[i] == 0 {
+			continue
+		}
+		a = append(a, m.elems[i].key)
+	}
+	return a
+}
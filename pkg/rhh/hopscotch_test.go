@@ -0,0 +1,161 @@
+package rhh
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testHopscotchMapPutDeleteGet runs the put/delete/get workload shared by
+// TestHopscotchMap_PutDeleteGet and TestHopscotchMap_SmallNeighborhood
+// against m, forcing several grows, and checks every operation against a
+// reference map[string]int.
+func testHopscotchMapPutDeleteGet(t *testing.T, m *HopscotchMap[[]byte, any]) {
+	t.Helper()
+	const n = 5000
+
+	ref := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		m.Put(key, i)
+		ref[string(key)] = i
+	}
+
+	if got, want := m.Len(), len(ref); got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+	for key, want := range ref {
+		got, ok := m.Get([]byte(key))
+		if !ok || got.(int) != want {
+			t.Fatalf("Get(%s) = %v, %v; want %d, true", key, got, ok, want)
+		}
+	}
+
+	// Delete every other key.
+	for i := 0; i < n; i += 2 {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if !m.Delete(key) {
+			t.Fatalf("Delete(%s) = false, want true", key)
+		}
+		delete(ref, string(key))
+	}
+
+	if got, want := m.Len(), len(ref); got != want {
+		t.Fatalf("Len() = %d, want %d after deletions", got, want)
+	}
+	for key, want := range ref {
+		got, ok := m.Get([]byte(key))
+		if !ok || got.(int) != want {
+			t.Fatalf("Get(%s) = %v, %v; want %d, true", key, got, ok, want)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if _, ok := m.Get(key); ok {
+			t.Fatalf("Get(%s) found deleted key", key)
+		}
+	}
+}
+
+// TestHopscotchMap_PutDeleteGet inserts, deletes, and looks up keys against
+// a reference map while starting from a small capacity, forcing several
+// grows along the way.
+func TestHopscotchMap_PutDeleteGet(t *testing.T) {
+	m := NewHopscotchMap[[]byte, any](BytesHasher{}, Options{Capacity: 16, LoadFactor: 90})
+	testHopscotchMapPutDeleteGet(t, m)
+}
+
+// TestHopscotchMap_SmallNeighborhood uses a small Neighborhood alongside a
+// small starting capacity, so inserts routinely find no bucket within H
+// slots of a full neighborhood willing to hop, exercising insert's
+// hop-failure fallback (grow + reinsert) rather than just the normal hop
+// path.
+func TestHopscotchMap_SmallNeighborhood(t *testing.T) {
+	m := NewHopscotchMap[[]byte, any](BytesHasher{}, Options{Capacity: 16, LoadFactor: 90, Neighborhood: 4})
+	testHopscotchMapPutDeleteGet(t, m)
+}
+
+// hopscotchBenchCapacity is the fixed table capacity used by the RH vs.
+// Hopscotch benchmarks; load factor is varied by how many of its slots are
+// filled, not by resizing.
+const hopscotchBenchCapacity = 1 << 16
+
+// fillHashMap inserts exactly as many keys as loadFactor implies (capacity *
+// loadFactor / 100) so the map never crosses its own grow threshold and
+// settles at precisely that load factor.
+func fillHashMap(capacity, loadFactor int) (*HashMap[[]byte, any], [][]byte) {
+	m := New[[]byte, any](BytesHasher{}, Options{Capacity: capacity, LoadFactor: loadFactor})
+	keys := benchKeys((capacity * loadFactor) / 100)
+	for i, k := range keys {
+		m.Put(k, i)
+	}
+	return m, keys
+}
+
+// fillHopscotchMap is fillHashMap's HopscotchMap equivalent. It inserts the
+// same number of keys, but a HopscotchMap also grows whenever insert finds no
+// entry it can hop, so the map can end at a larger capacity and a lower load
+// factor than loadFactor.
+func fillHopscotchMap(capacity, loadFactor int) (*HopscotchMap[[]byte, any], [][]byte) {
+	m := NewHopscotchMap[[]byte, any](BytesHasher{}, Options{Capacity: capacity, LoadFactor: loadFactor})
+	keys := benchKeys((capacity * loadFactor) / 100)
+	for i, k := range keys {
+		m.Put(k, i)
+	}
+	return m, keys
+}
+
+// BenchmarkRHVsHopscotch_Get compares HashMap (Robin Hood) against
+// HopscotchMap Get throughput at load factors 70/90/95%, the range where
+// HashMap's unbounded probe distances start to grow and HopscotchMap's
+// fixed-neighborhood lookups are meant to hold steady.
+func BenchmarkRHVsHopscotch_Get(b *testing.B) {
+	for _, lf := range []int{70, 90, 95} {
+		lf := lf
+
+		b.Run(fmt.Sprintf("RH/%d%%", lf), func(b *testing.B) {
+			m, keys := fillHashMap(hopscotchBenchCapacity, lf)
+			b.ReportMetric(m.AverageProbeCount(), "avg-probes")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Get(keys[i%len(keys)])
+			}
+		})
+
+		b.Run(fmt.Sprintf("Hopscotch/%d%%", lf), func(b *testing.B) {
+			m, keys := fillHopscotchMap(hopscotchBenchCapacity, lf)
+			b.ReportMetric(m.AverageProbeCount(), "avg-probes")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+// BenchmarkRHVsHopscotch_Put compares Put throughput the same way, since
+// HopscotchMap's hop-to-make-room logic makes Put more expensive than
+// HashMap's in exchange for Get's bounded cost.
+func BenchmarkRHVsHopscotch_Put(b *testing.B) {
+	for _, lf := range []int{70, 90, 95} {
+		lf := lf
+		keys := benchKeys((hopscotchBenchCapacity * lf) / 100)
+
+		b.Run(fmt.Sprintf("RH/%d%%", lf), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m := New[[]byte, any](BytesHasher{}, Options{Capacity: hopscotchBenchCapacity, LoadFactor: lf})
+				for j, k := range keys {
+					m.Put(k, j)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Hopscotch/%d%%", lf), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m := NewHopscotchMap[[]byte, any](BytesHasher{}, Options{Capacity: hopscotchBenchCapacity, LoadFactor: lf})
+				for j, k := range keys {
+					m.Put(k, j)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package rhh
+
+import "bytes"
+
+// legacyHashMap is a vendored copy of the pre-generics, []byte/interface{}
+// HashMap this package used before it was converted to generics. It's kept
+// test-only so BenchmarkLegacyHashMap_Put/_Get have something to compare
+// BenchmarkHashMap_Put/_Get against, since the generic HashMap replaced the
+// original in place rather than living alongside it.
+type legacyHashMap struct {
+	hashes []uint64
+	elems  []legacyHashElem
+
+	n          int
+	capacity   int
+	threshold  int
+	mask       uint64
+	loadFactor int
+}
+
+type legacyHashElem struct {
+	key   []byte
+	value interface{}
+	hash  uint64
+}
+
+func newLegacyHashMap(opt Options) *legacyHashMap {
+	m := &legacyHashMap{
+		capacity:   pow2(opt.Capacity),
+		loadFactor: opt.LoadFactor,
+	}
+	m.alloc()
+	return m
+}
+
+func (m *legacyHashMap) Get(key []byte) interface{} {
+	i := m.index(key)
+	if i == -1 {
+		return nil
+	}
+	return m.elems[i].value
+}
+
+func (m *legacyHashMap) Put(key []byte, val interface{}) {
+	m.n++
+	if m.n > m.threshold {
+		m.grow()
+	}
+
+	overwritten := m.insert(HashKey(key), key, val)
+	if overwritten {
+		m.n--
+	}
+}
+
+func (m *legacyHashMap) insert(hash uint64, key []byte, val interface{}) (overwritten bool) {
+	pos := int(hash & m.mask)
+	dist := 0
+
+	for {
+		if m.hashes[pos] == 0 {
+			m.hashes[pos] = hash
+			m.elems[pos] = legacyHashElem{hash: hash, key: key, value: val}
+			return false
+		} else if bytes.Equal(m.elems[pos].key, key) {
+			m.hashes[pos] = hash
+			m.elems[pos] = legacyHashElem{hash: hash, key: key, value: val}
+			return true
+		}
+
+		elemDist := legacyDist(m.hashes[pos], pos, m.capacity)
+		if elemDist < dist {
+			e := &m.elems[pos]
+			hash, m.hashes[pos] = m.hashes[pos], hash
+			key, e.key = e.key, key
+			val, e.value = e.value, val
+			dist = elemDist
+		}
+
+		pos = int((uint64(pos) + 1) & m.mask)
+		dist++
+	}
+}
+
+// alloc elems according to currently set capacity.
+func (m *legacyHashMap) alloc() {
+	m.elems = make([]legacyHashElem, m.capacity)
+	m.hashes = make([]uint64, m.capacity)
+	m.threshold = (m.capacity * m.loadFactor) / 100
+	m.mask = uint64(m.capacity - 1)
+}
+
+// grow doubles the capacity and reinserts all existing hashes & elements.
+func (m *legacyHashMap) grow() {
+	elems, hashes := m.elems, m.hashes
+	capacity := m.capacity
+
+	m.capacity *= 2
+	m.alloc()
+
+	for i := 0; i < capacity; i++ {
+		elem, hash := &elems[i], hashes[i]
+		if hash == 0 {
+			continue
+		}
+		m.insert(hash, elem.key, elem.value)
+	}
+}
+
+// index returns the position of key in the hash map.
+func (m *legacyHashMap) index(key []byte) int {
+	hash := HashKey(key)
+	pos := int(hash & m.mask)
+
+	dist := 0
+	for {
+		if m.hashes[pos] == 0 {
+			return -1
+		} else if dist > legacyDist(m.hashes[pos], pos, m.capacity) {
+			return -1
+		} else if m.hashes[pos] == hash && bytes.Equal(m.elems[pos].key, key) {
+			return pos
+		}
+
+		pos = int(uint64(pos+1) & m.mask)
+		dist++
+	}
+}
+
+// legacyDist is Dist's pre-Fibonacci-hashing formula (hash&mask, no shift),
+// matching legacyHashMap's hash&mask slot mapping.
+func legacyDist(hash uint64, i, capacity int) int {
+	mask := uint64(capacity - 1)
+	return int(uint64(i+capacity-int(hash&mask)) & mask)
+}
@@ -0,0 +1,281 @@
+//go:build !windows
+
+package rhh
+
+import (
+	"bytes"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// minArenaSize is the smallest mmap region offHeapArena will request, to
+// avoid a flurry of tiny mappings for small maps.
+const minArenaSize = 64 << 10
+
+// offHeapSlot is the fixed-size, pointer-free record stored per occupied
+// slot in an OffHeapMap. Key and value bytes live in a separate byte arena;
+// a slot only records their offset and length, so the slot array itself
+// never holds a live pointer and can be backed by mmap without the
+// garbage collector needing to scan it.
+type offHeapSlot struct {
+	hash   uint64
+	keyOff uint64
+	keyLen uint64
+	valOff uint64
+	valLen uint64
+}
+
+// OffHeapMap is a []byte-keyed, []byte-valued Robin Hood hash map whose
+// index and value bytes are allocated outside the Go heap via mmap, so that
+// even a map with hundreds of millions of entries is invisible to GC scans.
+// Use PutBytes/GetBytes rather than HashMap's Put/Get, which would have to
+// box values back into an interface{} on the heap.
+//
+// OffHeapMap is only available on platforms syscall.Mmap/Munmap support
+// (this file is gated //go:build !windows); there is no Windows stub, so
+// NewOffHeapMap does not exist in a Windows build. Callers that need an
+// off-heap map cross-platform should stick to HashMap/ShardedHashMap.
+type OffHeapMap struct {
+	slots    []offHeapSlot
+	rawSlots []byte
+	arena    *offHeapArena
+
+	n          int
+	capacity   int
+	threshold  int
+	mask       uint64
+	shift      uint
+	loadFactor int
+}
+
+// NewOffHeapMap returns a new OffHeapMap. opt.OffHeap must be true, as an
+// explicit acknowledgement that the returned map's memory isn't managed by
+// the Go runtime.
+func NewOffHeapMap(opt Options) *OffHeapMap {
+	if !opt.OffHeap {
+		panic("rhh: NewOffHeapMap requires Options.OffHeap to be set")
+	}
+
+	m := &OffHeapMap{
+		capacity:   pow2(opt.Capacity),
+		loadFactor: opt.LoadFactor,
+		arena:      newOffHeapArena(minArenaSize),
+	}
+	m.alloc()
+	return m
+}
+
+// PutBytes copies key and value into m's off-heap arena and associates
+// them, overwriting any existing value for key. Both slices are copied, so
+// the caller is free to reuse or modify them afterward. The arena is
+// append-only, so overwriting a key leaves its previous bytes stranded
+// until the map is rebuilt.
+func (m *OffHeapMap) PutBytes(key, value []byte) {
+	hash := HashKey(key)
+	keyOff, keyLen := m.arena.append(key)
+	valOff, valLen := m.arena.append(value)
+
+	m.n++
+	if m.n > m.threshold {
+		m.grow()
+	}
+
+	overwritten := m.insert(hash, keyOff, keyLen, valOff, valLen)
+	if overwritten {
+		m.n--
+	}
+}
+
+// GetBytes returns the value for key and whether it was found. The
+// returned slice aliases the map's arena and must not be modified, nor
+// retained past the map's next PutBytes, which may grow the arena and unmap
+// the region the slice points into.
+func (m *OffHeapMap) GetBytes(key []byte) ([]byte, bool) {
+	pos := m.index(key)
+	if pos == -1 {
+		return nil, false
+	}
+	s := m.slots[pos]
+	return m.arena.bytes(s.valOff, s.valLen), true
+}
+
+// Delete removes key from the map and returns true if the key existed.
+func (m *OffHeapMap) Delete(key []byte) bool {
+	pos := m.index(key)
+	if pos == -1 {
+		return false
+	}
+
+	m.remove(pos)
+	m.n--
+	return true
+}
+
+func (m *OffHeapMap) insert(hash, keyOff, keyLen, valOff, valLen uint64) (overwritten bool) {
+	pos := fibSlot(hash, m.shift)
+	dist := 0
+	s := offHeapSlot{hash: hash, keyOff: keyOff, keyLen: keyLen, valOff: valOff, valLen: valLen}
+
+	for {
+		cur := &m.slots[pos]
+		if cur.hash == 0 {
+			*cur = s
+			return false
+		} else if cur.hash == hash && bytes.Equal(m.arena.bytes(cur.keyOff, cur.keyLen), m.arena.bytes(keyOff, keyLen)) {
+			*cur = s
+			return true
+		}
+
+		// If the existing slot has probed less than us, swap places with it
+		// and keep going to find another slot for it.
+		elemDist := Dist(cur.hash, pos, m.capacity, m.shift)
+		if elemDist < dist {
+			s, *cur = *cur, s
+			dist = elemDist
+		}
+
+		pos = int((uint64(pos) + 1) & m.mask)
+		dist++
+	}
+}
+
+// remove deletes the slot at pos using the same backward-shift deletion as
+// HashMap.remove, so probe distances stay consistent with what index()
+// assumes.
+func (m *OffHeapMap) remove(pos int) {
+	m.slots[pos] = offHeapSlot{}
+
+	next := int((uint64(pos) + 1) & m.mask)
+	for m.slots[next].hash != 0 && Dist(m.slots[next].hash, next, m.capacity, m.shift) > 0 {
+		m.slots[pos] = m.slots[next]
+		m.slots[next] = offHeapSlot{}
+		pos, next = next, int((uint64(next)+1)&m.mask)
+	}
+}
+
+// index returns the position of key in the map, or -1 if it isn't present.
+func (m *OffHeapMap) index(key []byte) int {
+	hash := HashKey(key)
+	pos := fibSlot(hash, m.shift)
+
+	dist := 0
+	for {
+		s := m.slots[pos]
+		if s.hash == 0 {
+			return -1
+		} else if dist > Dist(s.hash, pos, m.capacity, m.shift) {
+			return -1
+		} else if s.hash == hash && bytes.Equal(m.arena.bytes(s.keyOff, s.keyLen), key) {
+			return pos
+		}
+
+		pos = int(uint64(pos+1) & m.mask)
+		dist++
+	}
+}
+
+// alloc mmaps a fresh slot array sized to the currently set capacity.
+func (m *OffHeapMap) alloc() {
+	slotSize := int(unsafe.Sizeof(offHeapSlot{}))
+	m.rawSlots = mmapBytes(m.capacity * slotSize)
+	m.slots = unsafe.Slice((*offHeapSlot)(unsafe.Pointer(&m.rawSlots[0])), m.capacity)
+	m.threshold = (m.capacity * m.loadFactor) / 100
+	m.mask = uint64(m.capacity - 1)
+	m.shift = ShiftFor(m.capacity)
+}
+
+// grow doubles the capacity, reinserts all existing slots, then unmaps the
+// old slot array. The arena grows independently, on demand, in append.
+func (m *OffHeapMap) grow() {
+	oldSlots, oldRaw := m.slots, m.rawSlots
+	capacity := m.capacity
+
+	m.capacity *= 2
+	m.alloc()
+
+	for i := 0; i < capacity; i++ {
+		s := oldSlots[i]
+		if s.hash == 0 {
+			continue
+		}
+		m.insert(s.hash, s.keyOff, s.keyLen, s.valOff, s.valLen)
+	}
+
+	munmapBytes(oldRaw)
+}
+
+// Len returns the number of key/values set in the map.
+func (m *OffHeapMap) Len() int { return m.n }
+
+// Cap returns the number of slots in the map.
+func (m *OffHeapMap) Cap() int { return m.capacity }
+
+// AverageProbeCount returns the average number of probes for each element.
+func (m *OffHeapMap) AverageProbeCount() float64 {
+	var sum float64
+	for i := 0; i < m.capacity; i++ {
+		s := m.slots[i]
+		if s.hash == 0 {
+			continue
+		}
+		sum += float64(Dist(s.hash, i, m.capacity, m.shift))
+	}
+	return sum/float64(m.n) + 1.0
+}
+
+// offHeapArena is an append-only, mmap'd byte buffer used to store key and
+// value bytes outside the Go heap. Growing doubles the mapping, copies the
+// old bytes across, and unmaps the old region.
+type offHeapArena struct {
+	buf []byte
+	len int
+}
+
+func newOffHeapArena(size int) *offHeapArena {
+	return &offHeapArena{buf: mmapBytes(size)}
+}
+
+// append copies b onto the end of the arena, growing it as needed, and
+// returns b's offset and length within the arena.
+func (a *offHeapArena) append(b []byte) (offset, length uint64) {
+	for a.len+len(b) > len(a.buf) {
+		a.grow()
+	}
+
+	offset = uint64(a.len)
+	copy(a.buf[a.len:], b)
+	a.len += len(b)
+	return offset, uint64(len(b))
+}
+
+// bytes returns the arena-owned slice at [offset, offset+length).
+func (a *offHeapArena) bytes(offset, length uint64) []byte {
+	return a.buf[offset : offset+length]
+}
+
+func (a *offHeapArena) grow() {
+	old := a.buf
+	a.buf = mmapBytes(len(old) * 2)
+	copy(a.buf, old)
+	munmapBytes(old)
+}
+
+// mmapBytes anonymously maps a read/write region of at least size bytes.
+func mmapBytes(size int) []byte {
+	if size < minArenaSize {
+		size = minArenaSize
+	}
+
+	b, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		panic(fmt.Sprintf("rhh: mmap: %s", err))
+	}
+	return b
+}
+
+func munmapBytes(b []byte) {
+	if err := syscall.Munmap(b); err != nil {
+		panic(fmt.Sprintf("rhh: munmap: %s", err))
+	}
+}
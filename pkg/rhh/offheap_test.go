@@ -0,0 +1,87 @@
+//go:build !windows
+
+package rhh
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// TestOffHeapMap_RoundTrip puts N key/value pairs, including forcing several
+// grows, then verifies every pair is still retrievable and deleting half of
+// them leaves the other half intact, exercising the mmap'd slot array and
+// arena across growth and backward-shift deletion.
+func TestOffHeapMap_RoundTrip(t *testing.T) {
+	const n = 20000
+
+	m := NewOffHeapMap(Options{Capacity: 16, LoadFactor: 90, OffHeap: true})
+	ref := make(map[string]string, n)
+
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		val := []byte(fmt.Sprintf("value-%d", i))
+		m.PutBytes(key, val)
+		ref[string(key)] = string(val)
+	}
+
+	if got, want := m.Len(), len(ref); got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+	if m.Cap() <= 16 {
+		t.Fatalf("Cap() = %d, want > 16 after %d puts (expected at least one grow)", m.Cap(), n)
+	}
+
+	for key, want := range ref {
+		got, ok := m.GetBytes([]byte(key))
+		if !ok || string(got) != want {
+			t.Fatalf("GetBytes(%s) = %q, %v; want %q, true", key, got, ok, want)
+		}
+	}
+
+	// Delete every other key and verify the rest survive.
+	for i := 0; i < n; i += 2 {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if !m.Delete(key) {
+			t.Fatalf("Delete(%s) = false, want true", key)
+		}
+		delete(ref, string(key))
+	}
+
+	if got, want := m.Len(), len(ref); got != want {
+		t.Fatalf("Len() = %d, want %d after deletions", got, want)
+	}
+	for key, want := range ref {
+		got, ok := m.GetBytes([]byte(key))
+		if !ok || string(got) != want {
+			t.Fatalf("GetBytes(%s) = %q, %v; want %q, true", key, got, ok, want)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if _, ok := m.GetBytes(key); ok {
+			t.Fatalf("GetBytes(%s) found deleted key", key)
+		}
+	}
+
+	if avg := m.AverageProbeCount(); avg > 4 {
+		t.Fatalf("AverageProbeCount() = %v, want <= 4 after deleting half the keys", avg)
+	}
+}
+
+// TestOffHeapMap_Overwrite verifies PutBytes overwrites an existing key's
+// value, including after the arena bytes for the old value are stranded.
+func TestOffHeapMap_Overwrite(t *testing.T) {
+	m := NewOffHeapMap(Options{Capacity: 16, LoadFactor: 90, OffHeap: true})
+
+	m.PutBytes([]byte("a"), []byte("first"))
+	m.PutBytes([]byte("a"), []byte("second"))
+
+	if got, want := m.Len(), 1; got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+	got, ok := m.GetBytes([]byte("a"))
+	if !ok || !bytes.Equal(got, []byte("second")) {
+		t.Fatalf("GetBytes(a) = %q, %v; want %q, true", got, ok, "second")
+	}
+}
@@ -2,26 +2,44 @@ package rhh
 
 import (
 	"bytes"
+	"encoding/binary"
+	"math/bits"
 	"sort"
 
 	"github.com/cespare/xxhash"
 )
 
+// Hasher hashes and compares keys of type K for use in a HashMap. Hash must
+// return a non-zero value, since zero is used internally to mark empty
+// slots.
+type Hasher[K any] interface {
+	// Hash returns a non-zero hash of key.
+	Hash(key K) uint64
+
+	// Equal returns true if a and b are equal.
+	Equal(a, b K) bool
+}
+
 // HashMap represents a hash map that implements Robin Hood Hashing.
 // https://cs.uwaterloo.ca/research/tr/1986/CS-86-14.pdf
-type HashMap struct {
+type HashMap[K any, V any] struct {
+	hasher Hasher[K]
+
 	hashes []uint64
-	elems  []hashElem
+	elems  []hashElem[K, V]
 
 	n          int
 	capacity   int
 	threshold  int
 	mask       uint64
+	shift      uint
 	loadFactor int
 }
 
-func NewHashMap(opt Options) *HashMap {
-	m := &HashMap{
+// New returns a new HashMap that hashes and compares keys using hasher.
+func New[K any, V any](hasher Hasher[K], opt Options) *HashMap[K, V] {
+	m := &HashMap[K, V]{
+		hasher:     hasher,
 		capacity:   pow2(opt.Capacity), // Limited to 2^64.
 		loadFactor: opt.LoadFactor,
 	}
@@ -29,15 +47,18 @@ func NewHashMap(opt Options) *HashMap {
 	return m
 }
 
-func (m *HashMap) Get(key []byte) interface{} {
+// Get returns the value for key and whether it was found.
+func (m *HashMap[K, V]) Get(key K) (value V, ok bool) {
 	i := m.index(key)
 	if i == -1 {
-		return nil
+		var zero V
+		return zero, false
 	}
-	return m.elems[i].value
+	return m.elems[i].value, true
 }
 
-func (m *HashMap) Put(key []byte, val interface{}) {
+// Put inserts key and val into the map, overwriting any existing value.
+func (m *HashMap[K, V]) Put(key K, val V) {
 	// Grow the map if we've run out of slots.
 	m.n++
 	if m.n > m.threshold {
@@ -45,14 +66,26 @@ func (m *HashMap) Put(key []byte, val interface{}) {
 	}
 
 	// If the key was overwritten then decrement the size.
-	overwritten := m.insert(HashKey(key), key, val)
+	overwritten := m.insert(m.hasher.Hash(key), key, val)
 	if overwritten {
 		m.n--
 	}
 }
 
-func (m *HashMap) insert(hash uint64, key []byte, val interface{}) (overwritten bool) {
-	pos := int(hash & m.mask)
+// Delete removes key from the hash map and returns true if the key existed.
+func (m *HashMap[K, V]) Delete(key K) bool {
+	pos := m.index(key)
+	if pos == -1 {
+		return false
+	}
+
+	m.remove(pos)
+	m.n--
+	return true
+}
+
+func (m *HashMap[K, V]) insert(hash uint64, key K, val V) (overwritten bool) {
+	pos := fibSlot(hash, m.shift)
 	dist := 0
 
 	// Continue searching until we find an empty slot or lower probe distance.
@@ -60,17 +93,17 @@ func (m *HashMap) insert(hash uint64, key []byte, val interface{}) (overwritten
 		// Empty slot found or matching key, insert and exit.
 		if m.hashes[pos] == 0 {
 			m.hashes[pos] = hash
-			m.elems[pos] = hashElem{hash: hash, key: key, value: val}
+			m.elems[pos] = hashElem[K, V]{hash: hash, key: key, value: val}
 			return false
-		} else if bytes.Equal(m.elems[pos].key, key) {
+		} else if m.hasher.Equal(m.elems[pos].key, key) {
 			m.hashes[pos] = hash
-			m.elems[pos] = hashElem{hash: hash, key: key, value: val}
+			m.elems[pos] = hashElem[K, V]{hash: hash, key: key, value: val}
 			return true
 		}
 
 		// If the existing elem has probed less than us, then swap places with
 		// existing elem, and keep going to find another slot for that elem.
-		elemDist := Dist(m.hashes[pos], pos, m.capacity)
+		elemDist := Dist(m.hashes[pos], pos, m.capacity, m.shift)
 		if elemDist < dist {
 			// Swap with current position.
 			e := &m.elems[pos]
@@ -88,16 +121,38 @@ func (m *HashMap) insert(hash uint64, key []byte, val interface{}) (overwritten
 	}
 }
 
+// remove deletes the element at pos using backward-shift deletion: slots
+// that follow pos are shifted back one at a time as long as they still have
+// a non-zero probe distance, which keeps every remaining element's distance
+// consistent with what index() assumes and avoids leaving a tombstone behind.
+func (m *HashMap[K, V]) remove(pos int) {
+	var zero hashElem[K, V]
+	m.hashes[pos] = 0
+	m.elems[pos] = zero
+
+	next := int((uint64(pos) + 1) & m.mask)
+	for m.hashes[next] != 0 && Dist(m.hashes[next], next, m.capacity, m.shift) > 0 {
+		m.hashes[pos] = m.hashes[next]
+		m.elems[pos] = m.elems[next]
+
+		m.hashes[next] = 0
+		m.elems[next] = zero
+
+		pos, next = next, int((uint64(next)+1)&m.mask)
+	}
+}
+
 // alloc elems according to currently set capacity.
-func (m *HashMap) alloc() {
-	m.elems = make([]hashElem, m.capacity)
+func (m *HashMap[K, V]) alloc() {
+	m.elems = make([]hashElem[K, V], m.capacity)
 	m.hashes = make([]uint64, m.capacity)
 	m.threshold = (m.capacity * m.loadFactor) / 100
 	m.mask = uint64(m.capacity - 1)
+	m.shift = ShiftFor(m.capacity)
 }
 
 // grow doubles the capacity and reinserts all existing hashes & elements.
-func (m *HashMap) grow() {
+func (m *HashMap[K, V]) grow() {
 	// Copy old elements and hashes.
 	elems, hashes := m.elems, m.hashes
 	capacity := m.capacity
@@ -117,17 +172,17 @@ func (m *HashMap) grow() {
 }
 
 // index returns the position of key in the hash map.
-func (m *HashMap) index(key []byte) int {
-	hash := HashKey(key)
-	pos := int(hash & m.mask)
+func (m *HashMap[K, V]) index(key K) int {
+	hash := m.hasher.Hash(key)
+	pos := fibSlot(hash, m.shift)
 
 	dist := 0
 	for {
 		if m.hashes[pos] == 0 {
 			return -1
-		} else if dist > Dist(m.hashes[pos], pos, m.capacity) {
+		} else if dist > Dist(m.hashes[pos], pos, m.capacity, m.shift) {
 			return -1
-		} else if m.hashes[pos] == hash && bytes.Equal(m.elems[pos].key, key) {
+		} else if m.hashes[pos] == hash && m.hasher.Equal(m.elems[pos].key, key) {
 			return pos
 		}
 
@@ -137,51 +192,50 @@ func (m *HashMap) index(key []byte) int {
 }
 
 // Elem returns the i-th key/value pair of the hash map.
-func (m *HashMap) Elem(i int) (key []byte, value interface{}) {
-	if i >= len(m.elems) {
-		return nil, nil
+func (m *HashMap[K, V]) Elem(i int) (key K, value V, ok bool) {
+	if i >= len(m.elems) || m.hashes[i] == 0 {
+		return key, value, false
 	}
 
 	e := &m.elems[i]
-	return e.key, e.value
+	return e.key, e.value, true
 }
 
 // Len returns the number of key/values set in map.
-func (m *HashMap) Len() int { return m.n }
+func (m *HashMap[K, V]) Len() int { return m.n }
 
 // Cap returns the number of key/values set in map.
-func (m *HashMap) Cap() int { return m.capacity }
+func (m *HashMap[K, V]) Cap() int { return m.capacity }
 
 // AverageProbeCount returns the average number of probes for each element.
-func (m *HashMap) AverageProbeCount() float64 {
+func (m *HashMap[K, V]) AverageProbeCount() float64 {
 	var sum float64
 	for i := 0; i < m.capacity; i++ {
 		hash := m.hashes[i]
 		if hash == 0 {
 			continue
 		}
-		sum += float64(Dist(hash, i, m.capacity))
+		sum += float64(Dist(hash, i, m.capacity, m.shift))
 	}
 	return sum/float64(m.n) + 1.0
 }
 
-// Keys returns a list of sorted keys.
-func (m *HashMap) Keys() [][]byte {
-	a := make([][]byte, 0, m.Len())
-	for i := 0; i < m.Cap(); i++ {
-		k, v := m.Elem(i)
-		if v == nil {
+// Keys returns an unsorted list of keys in the map.
+func (m *HashMap[K, V]) Keys() []K {
+	a := make([]K, 0, m.Len())
+	for i := 0; i < m.capacity; i++ {
+		k, _, ok := m.Elem(i)
+		if !ok {
 			continue
 		}
 		a = append(a, k)
 	}
-	sort.Sort(byteSlices(a))
 	return a
 }
 
-type hashElem struct {
-	key   []byte
-	value interface{}
+type hashElem[K any, V any] struct {
+	key   K
+	value V
 	hash  uint64
 }
 
@@ -189,6 +243,16 @@ type hashElem struct {
 type Options struct {
 	Capacity   int
 	LoadFactor int
+
+	// Neighborhood is the number of slots following a bucket that are
+	// considered part of its neighborhood. It is only used by HopscotchMap;
+	// HashMap ignores it. Zero uses DefaultHopscotchNeighborhood.
+	Neighborhood int
+
+	// OffHeap must be set to acknowledge that NewOffHeapMap's index and
+	// value bytes are allocated outside the Go heap via mmap. It is only
+	// read by NewOffHeapMap; HashMap and HopscotchMap ignore it.
+	OffHeap bool
 }
 
 // DefaultOptions represents a default set of options to pass to NewHashMap().
@@ -197,20 +261,41 @@ var DefaultOptions = Options{
 	LoadFactor: 90,
 }
 
-// HashKey computes a hash of key. Hash is always non-zero.
-func HashKey(key []byte) uint64 {
-	h := xxhash.Sum64(key)
-	if h == 0 {
-		h = 1
-	}
-	return h
+// hashSeed is the multiplier used by fibSlot. It is seeded once at package
+// init from the 64-bit golden ratio constant (2^64 / φ), which is what gives
+// Fibonacci hashing its even bit-spreading: every output bit depends on
+// every input bit, unlike masking off the low bits of hash directly.
+var hashSeed uint64
+
+func init() {
+	hashSeed = 11400714819323198485
+}
+
+// fibSlot maps hash onto a slot in a table of 2^(64-shift) capacity using
+// Fibonacci hashing. This mixes low-entropy hashes (e.g. xxhash of short,
+// monotonically increasing keys) far more evenly across the table than
+// masking off hash's low bits. It does not change the rule that hashes are
+// non-zero, since zero still marks an empty slot.
+func fibSlot(hash uint64, shift uint) int {
+	return int((hash * hashSeed) >> shift)
+}
+
+// ShiftFor returns the shift Dist and fibSlot expect for a table of the
+// given capacity: 64 - log2(capacity). Capacity must be a power of 2.
+func ShiftFor(capacity int) uint {
+	return 64 - uint(bits.TrailingZeros64(uint64(capacity)))
 }
 
 // Dist returns the probe distance for a hash in a slot index.
-// NOTE: Capacity must be a power of 2.
-func Dist(hash uint64, i, capacity int) int {
+//
+// NOTE: Capacity must be a power of 2. NOTE: this is a breaking signature
+// change from the pre-Fibonacci-hashing Dist(hash, i, capacity) — capacity
+// alone no longer determines a hash's ideal slot, so callers must also pass
+// the table's shift (computable via ShiftFor(capacity) if not already on
+// hand).
+func Dist(hash uint64, i, capacity int, shift uint) int {
 	mask := uint64(capacity - 1)
-	dist := int(uint64(i+capacity-int(hash&mask)) & mask)
+	dist := int(uint64(i+capacity-fibSlot(hash, shift)) & mask)
 	return dist
 }
 
@@ -225,8 +310,96 @@ func pow2(v int) int {
 	panic("unreachable")
 }
 
+// BytesHasher hashes and compares []byte keys using xxhash.
+type BytesHasher struct{}
+
+// Hash returns a non-zero xxhash of key.
+func (BytesHasher) Hash(key []byte) uint64 { return HashKey(key) }
+
+// Equal returns true if a and b contain the same bytes.
+func (BytesHasher) Equal(a, b []byte) bool { return bytes.Equal(a, b) }
+
+// StringHasher hashes and compares string keys using xxhash.
+type StringHasher struct{}
+
+// Hash returns a non-zero xxhash of key.
+func (StringHasher) Hash(key string) uint64 {
+	h := xxhash.Sum64String(key)
+	if h == 0 {
+		h = 1
+	}
+	return h
+}
+
+// Equal returns true if a and b are the same string.
+func (StringHasher) Equal(a, b string) bool { return a == b }
+
+// Integer is the set of built-in integer types supported by IntHasher.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// IntHasher hashes and compares integer keys of type K by xxhashing their
+// little-endian encoding.
+type IntHasher[K Integer] struct{}
+
+// Hash returns a non-zero hash of key.
+func (IntHasher[K]) Hash(key K) uint64 {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(key))
+	h := xxhash.Sum64(buf[:])
+	if h == 0 {
+		h = 1
+	}
+	return h
+}
+
+// Equal returns true if a and b are the same value.
+func (IntHasher[K]) Equal(a, b K) bool { return a == b }
+
+// BytesHashMap wraps a []byte-keyed HashMap and restores the sorted-Keys()
+// guarantee the original, pre-generics implementation made, so existing
+// callers of rhh.NewHashMap(opt).Keys() keep seeing the same stable order
+// instead of silently getting HashMap[K, V]'s unsorted one.
+type BytesHashMap struct {
+	*HashMap[[]byte, any]
+}
+
+// NewHashMap returns a BytesHashMap using the built-in BytesHasher, so
+// existing callers can keep calling rhh.NewHashMap(opt) without naming the
+// key/value types themselves.
+func NewHashMap(opt Options) *BytesHashMap {
+	return &BytesHashMap{New[[]byte, any](BytesHasher{}, opt)}
+}
+
+// Keys returns a sorted list of keys, matching the original HashMap's
+// ordering guarantee.
+func (m *BytesHashMap) Keys() [][]byte {
+	a := m.HashMap.Keys()
+	sort.Sort(byteSlices(a))
+	return a
+}
+
+// SortedBytesKeys returns a sorted list of keys from a []byte-keyed map,
+// matching the ordering the original HashMap.Keys() guaranteed.
+func SortedBytesKeys[V any](m *HashMap[[]byte, V]) [][]byte {
+	a := m.Keys()
+	sort.Sort(byteSlices(a))
+	return a
+}
+
+// HashKey computes a hash of key. Hash is always non-zero.
+func HashKey(key []byte) uint64 {
+	h := xxhash.Sum64(key)
+	if h == 0 {
+		h = 1
+	}
+	return h
+}
+
 type byteSlices [][]byte
 
 func (a byteSlices) Len() int           { return len(a) }
 func (a byteSlices) Less(i, j int) bool { return bytes.Compare(a[i], a[j]) == -1 }
-func (a byteSlices) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
\ No newline at end of file
+func (a byteSlices) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
@@ -0,0 +1,281 @@
+package rhh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestHashMap_Delete inserts N keys, deletes half of them, verifies the
+// remaining lookups against a reference map, and checks that the average
+// probe count stays low. This exercises the backward-shift deletion invariant that every
+// surviving element's probe distance still matches what index() assumes.
+func TestHashMap_Delete(t *testing.T) {
+	const n = 10000
+
+	m := NewHashMap(Options{Capacity: 256, LoadFactor: 90})
+	ref := make(map[string]int, n)
+
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		m.Put(key, i)
+		ref[string(key)] = i
+	}
+
+	// Delete every other key.
+	for i := 0; i < n; i += 2 {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if !m.Delete(key) {
+			t.Fatalf("Delete(%s) = false, want true", key)
+		}
+		delete(ref, string(key))
+	}
+
+	if got, want := m.Len(), len(ref); got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+
+	for key, want := range ref {
+		got, ok := m.Get([]byte(key))
+		if !ok || got.(int) != want {
+			t.Fatalf("Get(%s) = %v, %v; want %d, true", key, got, ok, want)
+		}
+	}
+
+	for i := 0; i < n; i += 2 {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if _, ok := m.Get(key); ok {
+			t.Fatalf("Get(%s) found deleted key", key)
+		}
+	}
+
+	// Every surviving element must still be reachable by index()'s
+	// Dist-bounded probe, i.e. deletion must not leave tombstone-style gaps
+	// that make AverageProbeCount blow up.
+	if avg := m.AverageProbeCount(); avg > 4 {
+		t.Fatalf("AverageProbeCount() = %v, want <= 4 after deleting half the keys", avg)
+	}
+}
+
+// TestHashMap_Delete_Random churns random inserts and deletes against a
+// reference map, verifying lookups stay consistent throughout.
+func TestHashMap_Delete_Random(t *testing.T) {
+	m := NewHashMap(Options{Capacity: 16, LoadFactor: 90})
+	ref := make(map[string]int)
+
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 20000; i++ {
+		key := fmt.Sprintf("key-%d", rnd.Intn(5000))
+		if rnd.Intn(3) == 2 {
+			m.Delete([]byte(key))
+			delete(ref, key)
+			continue
+		}
+		m.Put([]byte(key), i)
+		ref[key] = i
+	}
+
+	if got, want := m.Len(), len(ref); got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+	for key, want := range ref {
+		got, ok := m.Get([]byte(key))
+		if !ok || got.(int) != want {
+			t.Fatalf("Get(%s) = %v, %v; want %d, true", key, got, ok, want)
+		}
+	}
+}
+
+// TestBytesHashMap_Keys verifies BytesHashMap.Keys() still returns keys in
+// sorted order, matching the pre-generics HashMap's guarantee.
+func TestBytesHashMap_Keys(t *testing.T) {
+	m := NewHashMap(Options{Capacity: 16, LoadFactor: 90})
+	want := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	for _, k := range []string{"c", "a", "d", "b"} {
+		m.Put([]byte(k), nil)
+	}
+
+	got := m.Keys()
+	if len(got) != len(want) {
+		t.Fatalf("Keys() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Fatalf("Keys()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if !sort.IsSorted(byteSlices(got)) {
+		t.Fatalf("Keys() = %v, not sorted", got)
+	}
+}
+
+const benchN = 1 << 20 // 1M entries, per the requested Put/Get benchmark size.
+
+func benchKeys(n int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("bench-key-%d", i))
+	}
+	return keys
+}
+
+// BenchmarkHashMap_Put and BenchmarkHashMap_Get establish the generic
+// HashMap's Put/Get throughput at 1M entries, to compare against
+// BenchmarkLegacyHashMap_Put/_Get below.
+//
+// BenchmarkHashMap_Put measures Put throughput for the generic HashMap at
+// 1M entries.
+func BenchmarkHashMap_Put(b *testing.B) {
+	keys := benchKeys(benchN)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := NewHashMap(DefaultOptions)
+		for _, k := range keys {
+			m.Put(k, i)
+		}
+	}
+}
+
+// BenchmarkHashMap_Get measures Get throughput for the generic HashMap at
+// 1M entries.
+func BenchmarkHashMap_Get(b *testing.B) {
+	keys := benchKeys(benchN)
+	m := NewHashMap(DefaultOptions)
+	for i, k := range keys {
+		m.Put(k, i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i%len(keys)])
+	}
+}
+
+// BenchmarkLegacyHashMap_Put and BenchmarkLegacyHashMap_Get run the same
+// Put/Get workload against legacyHashMap, the vendored pre-generics
+// []byte/interface{} map, so the generic HashMap's numbers above have
+// something concrete to be measured against rather than standing alone.
+func BenchmarkLegacyHashMap_Put(b *testing.B) {
+	keys := benchKeys(benchN)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := newLegacyHashMap(DefaultOptions)
+		for _, k := range keys {
+			m.Put(k, i)
+		}
+	}
+}
+
+func BenchmarkLegacyHashMap_Get(b *testing.B) {
+	keys := benchKeys(benchN)
+	m := newLegacyHashMap(DefaultOptions)
+	for i, k := range keys {
+		m.Put(k, i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i%len(keys)])
+	}
+}
+
+// sequentialKeys returns n keys that are the little-endian encoding of
+// monotonically increasing integers 0..n-1 — the adversarial pattern
+// Fibonacci hashing targets, since a naive hash&mask slot mapping leaves
+// runs of sequential keys clustered in the low bits of their hash.
+func sequentialKeys(n int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, uint64(i))
+		keys[i] = buf
+	}
+	return keys
+}
+
+// naiveMaskProbeCount simulates the pre-Fibonacci Robin Hood insert, which
+// picked a key's home slot via hash&mask instead of fibSlot, and returns the
+// resulting average probe count. It exists only to give
+// TestFibonacciHashing_ReducesSequentialKeyProbeCount something to compare
+// HashMap's actual AverageProbeCount() against.
+func naiveMaskProbeCount(keys [][]byte, capacity int) float64 {
+	hashes := make([]uint64, capacity)
+	mask := uint64(capacity - 1)
+
+	for _, k := range keys {
+		hash := HashKey(k)
+		pos := int(hash & mask)
+		dist := 0
+		for {
+			if hashes[pos] == 0 {
+				hashes[pos] = hash
+				break
+			}
+			existingDist := int(uint64(pos+capacity-int(hashes[pos]&mask)) & mask)
+			if existingDist < dist {
+				hashes[pos], hash = hash, hashes[pos]
+				dist = existingDist
+			}
+			pos = int((uint64(pos) + 1) & mask)
+			dist++
+		}
+	}
+
+	var sum float64
+	var n int
+	for i, hash := range hashes {
+		if hash == 0 {
+			continue
+		}
+		sum += float64(int(uint64(i+capacity-int(hash&mask)) & mask))
+		n++
+	}
+	return sum/float64(n) + 1.0
+}
+
+// TestFibonacciHashing_ReducesSequentialKeyProbeCount demonstrates the core
+// claim behind switching HashMap's slot mapping to Fibonacci hashing: it
+// substantially lowers probe distances for low-entropy, sequential keys
+// compared to the masking scheme it replaced.
+func TestFibonacciHashing_ReducesSequentialKeyProbeCount(t *testing.T) {
+	const capacity = 1 << 14
+	keys := sequentialKeys((capacity * 90) / 100)
+
+	m := NewHashMap(Options{Capacity: capacity, LoadFactor: 90})
+	for i, k := range keys {
+		m.Put(k, i)
+	}
+
+	fibAvg := m.AverageProbeCount()
+	naiveAvg := naiveMaskProbeCount(keys, capacity)
+	t.Logf("sequential keys avg probe count: fibonacci=%.2f mask=%.2f", fibAvg, naiveAvg)
+
+	if fibAvg >= naiveAvg {
+		t.Fatalf("fibonacci hashing did not reduce probe count for sequential keys: fibonacci=%.2f, mask=%.2f", fibAvg, naiveAvg)
+	}
+}
+
+// BenchmarkHashMap_Get_SequentialKeys measures Get throughput for sequential
+// integer-encoded keys, reporting the average probe count alongside it so a
+// regression in Fibonacci hashing's handling of this key pattern shows up in
+// both throughput and probe-count metrics.
+func BenchmarkHashMap_Get_SequentialKeys(b *testing.B) {
+	const capacity = 1 << 20
+	keys := sequentialKeys((capacity * 90) / 100)
+
+	m := NewHashMap(Options{Capacity: capacity, LoadFactor: 90})
+	for i, k := range keys {
+		m.Put(k, i)
+	}
+	b.ReportMetric(m.AverageProbeCount(), "avg-probes")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i%len(keys)])
+	}
+}
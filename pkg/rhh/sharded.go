@@ -0,0 +1,126 @@
+package rhh
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// ShardedHashMap spreads keys across N independent HashMap shards, each
+// guarded by its own sync.RWMutex, instead of the single external mutex
+// callers previously had to wrap around a whole HashMap. This lets reads and
+// writes to different shards proceed concurrently, which matters for
+// read-heavy, lock-bottlenecked lookups like the tsi1 series file's.
+type ShardedHashMap[K any, V any] struct {
+	hasher     Hasher[K]
+	shards     []*shardedMapShard[K, V]
+	shardShift uint
+}
+
+type shardedMapShard[K any, V any] struct {
+	mu sync.RWMutex
+	m  *HashMap[K, V]
+}
+
+// NewShardedHashMap returns a ShardedHashMap with n shards, each an
+// independent HashMap built with hasher and opt. n is rounded up to the next
+// power of 2; if n <= 0, runtime.GOMAXPROCS(0) is used.
+func NewShardedHashMap[K any, V any](hasher Hasher[K], n int, opt Options) *ShardedHashMap[K, V] {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	n = pow2(n)
+
+	shards := make([]*shardedMapShard[K, V], n)
+	for i := range shards {
+		shards[i] = &shardedMapShard[K, V]{m: New[K, V](hasher, opt)}
+	}
+
+	return &ShardedHashMap[K, V]{
+		hasher:     hasher,
+		shards:     shards,
+		shardShift: 64 - uint(bits.TrailingZeros64(uint64(n))),
+	}
+}
+
+// shard returns the shard responsible for key, selected from the top bits of
+// its hash. This keeps shard selection independent of the high bits HashMap's
+// Fibonacci mapping (fibSlot) uses to pick a slot within a shard.
+func (m *ShardedHashMap[K, V]) shard(key K) *shardedMapShard[K, V] {
+	hash := m.hasher.Hash(key)
+	return m.shards[hash>>m.shardShift]
+}
+
+// Get returns the value for key and whether it was found.
+func (m *ShardedHashMap[K, V]) Get(key K) (V, bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	v, ok := s.m.Get(key)
+	s.mu.RUnlock()
+	return v, ok
+}
+
+// Put inserts key and val into the map, overwriting any existing value.
+func (m *ShardedHashMap[K, V]) Put(key K, val V) {
+	s := m.shard(key)
+	s.mu.Lock()
+	s.m.Put(key, val)
+	s.mu.Unlock()
+}
+
+// Delete removes key from the hash map and returns true if the key existed.
+func (m *ShardedHashMap[K, V]) Delete(key K) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	ok := s.m.Delete(key)
+	s.mu.Unlock()
+	return ok
+}
+
+// Len returns the number of key/values set across all shards.
+func (m *ShardedHashMap[K, V]) Len() int {
+	var n int
+	for _, s := range m.shards {
+		s.mu.RLock()
+		n += s.m.Len()
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// Range calls fn for every key/value pair in the map, stopping early if fn
+// returns false. Each shard is snapshotted under its own RLock independently
+// of the others, so a key concurrently written to a different shard may or
+// may not be observed, but fn is never called while holding a shard's lock.
+func (m *ShardedHashMap[K, V]) Range(fn func(key K, val V) bool) {
+	for _, s := range m.shards {
+		s.mu.RLock()
+		keys := make([]K, 0, s.m.Len())
+		vals := make([]V, 0, s.m.Len())
+		for i := 0; i < s.m.Cap(); i++ {
+			k, v, ok := s.m.Elem(i)
+			if !ok {
+				continue
+			}
+			keys = append(keys, k)
+			vals = append(vals, v)
+		}
+		s.mu.RUnlock()
+
+		for i := range keys {
+			if !fn(keys[i], vals[i]) {
+				return
+			}
+		}
+	}
+}
+
+// BytesShardedHashMap is a ShardedHashMap keyed on []byte, matching the
+// value type used by the original, unsharded HashMap.
+type BytesShardedHashMap = ShardedHashMap[[]byte, any]
+
+// NewShardedBytesHashMap returns a BytesShardedHashMap using the built-in
+// BytesHasher.
+func NewShardedBytesHashMap(n int, opt Options) *BytesShardedHashMap {
+	return NewShardedHashMap[[]byte, any](BytesHasher{}, n, opt)
+}
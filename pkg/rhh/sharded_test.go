@@ -0,0 +1,47 @@
+package rhh
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// BenchmarkShardedHashMap_MixedGetPut measures mixed Get/Put throughput
+// against a BytesShardedHashMap at 1/2/4/8/16 concurrent goroutines, to show
+// the scaling per-shard locking is meant to provide over a single mutex
+// guarding one HashMap.
+func BenchmarkShardedHashMap_MixedGetPut(b *testing.B) {
+	const prefillN = 1 << 16
+	keys := benchKeys(prefillN)
+
+	for _, goroutines := range []int{1, 2, 4, 8, 16} {
+		goroutines := goroutines
+
+		b.Run(fmt.Sprintf("goroutines=%d", goroutines), func(b *testing.B) {
+			m := NewShardedBytesHashMap(0, DefaultOptions)
+			for i, k := range keys {
+				m.Put(k, i)
+			}
+
+			perGoroutine := (b.N + goroutines - 1) / goroutines
+			b.ResetTimer()
+
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(offset int) {
+					defer wg.Done()
+					for i := 0; i < perGoroutine; i++ {
+						k := keys[(offset+i)%len(keys)]
+						if i%10 == 0 {
+							m.Put(k, i)
+						} else {
+							m.Get(k)
+						}
+					}
+				}(g * (len(keys) / goroutines))
+			}
+			wg.Wait()
+		})
+	}
+}